@@ -0,0 +1,182 @@
+// This lives in the top-level wtr package rather than a separate wtr/diff
+// package: without a go.mod, there is no module path a nested package
+// could use to import the types (LicenceCollection, LicenceRow) it
+// diffs, so it stays alongside them instead.
+package wtr
+
+import (
+	"bufio"
+	"encoding/csv"
+)
+
+// siteKey identifies the licence and site (SID lat/long) a row belongs
+// to, independent of any field we want Diff to be able to report as
+// Changed. It is not on its own unique: a licence may have several rows
+// at the same site (e.g. several simultaneous frequency assignments), so
+// siteKey alone would collapse them onto one another.
+type siteKey struct {
+	licenceNumber string
+	sidLat        string
+	sidLong       string
+}
+
+func siteKeyOf(row *LicenceRow) siteKey {
+	return siteKey{
+		licenceNumber: row.LicenceNumber,
+		sidLat:        row.SidLatNS + row.SidLatDeg + row.SidLatMin + row.SidLatSec,
+		sidLong:       row.SidLongEW + row.SidLongDeg + row.SidLongMin + row.SidLongSec,
+	}
+}
+
+// rowKey identifies a single station/frequency assignment within a
+// licence. Besides the site, it carries ordinal: the row's position
+// among the rows sharing that same site, in the order they appear in
+// their collection. That disambiguates several simultaneous frequency
+// assignments at one site (which share a siteKey) without consuming
+// Frequency or NGR themselves, both of which are exactly the kind of
+// field a republished WTR.csv is likely to change (frequency
+// reassignments, site NGR corrections) and so must stay comparable in
+// changedFields. This assumes Ofcom's export lists a site's rows in the
+// same relative order from one snapshot to the next.
+type rowKey struct {
+	siteKey
+	ordinal int
+}
+
+// keysFor returns each of rows' rowKey, keyed by row identity.
+func keysFor(rows []*LicenceRow) map[*LicenceRow]rowKey {
+	ordinals := make(map[siteKey]int, len(rows))
+	keys := make(map[*LicenceRow]rowKey, len(rows))
+	for _, row := range rows {
+		site := siteKeyOf(row)
+		keys[row] = rowKey{siteKey: site, ordinal: ordinals[site]}
+		ordinals[site]++
+	}
+	return keys
+}
+
+// ChangedRow pairs the old and new revisions of a row that is present in
+// both collections but differs, together with the names of the fields
+// that changed.
+type ChangedRow struct {
+	Old    *LicenceRow
+	New    *LicenceRow
+	Fields []string
+}
+
+// LicenceDiff is the result of comparing two LicenceCollection snapshots
+// of the WTR register.
+type LicenceDiff struct {
+	header  []string
+	schema  Schema
+	Added   []*LicenceRow
+	Removed []*LicenceRow
+	Changed []ChangedRow
+}
+
+// Diff compares old and new, matching rows by Licence Number, SID
+// lat/long, and position among the other rows at that same site, to
+// distinguish the several rows a multi-row licence may have (e.g. several
+// simultaneous frequency assignments at one site). Rows present only in
+// new are Added, rows present only in old are Removed, and rows present
+// in both but differing in one or more fields (including Frequency and
+// NGR) are reported as Changed.
+func Diff(old, new *LicenceCollection) *LicenceDiff {
+	oldKeys := keysFor(old.rows)
+	newKeys := keysFor(new.rows)
+
+	oldByKey := make(map[rowKey]*LicenceRow, len(old.rows))
+	for _, row := range old.rows {
+		oldByKey[oldKeys[row]] = row
+	}
+
+	diff := &LicenceDiff{header: new.header, schema: new.schema}
+
+	seen := make(map[rowKey]bool, len(new.rows))
+	for _, newRow := range new.rows {
+		key := newKeys[newRow]
+		seen[key] = true
+
+		oldRow, ok := oldByKey[key]
+		if !ok {
+			diff.Added = append(diff.Added, newRow)
+			continue
+		}
+		if fields := changedFields(oldRow, newRow, new.schema); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, ChangedRow{Old: oldRow, New: newRow, Fields: fields})
+		}
+	}
+
+	for _, oldRow := range old.rows {
+		if !seen[oldKeys[oldRow]] {
+			diff.Removed = append(diff.Removed, oldRow)
+		}
+	}
+
+	return diff
+}
+
+// changedFields returns the names, taken from schema's header, of every
+// column that differs between oldRow and newRow. Both rows are rendered
+// with schema (the new collection's schema) regardless of which schema
+// originally parsed them, so that a column a prior schema didn't carry
+// (e.g. Licence Expiry Date) is correctly reported as changed rather
+// than silently ignored.
+func changedFields(oldRow, newRow *LicenceRow, schema Schema) []string {
+	oldRecord := schema.Render(oldRow)
+	newRecord := schema.Render(newRow)
+
+	var fields []string
+	for i, name := range schema.Header() {
+		if i < len(oldRecord) && i < len(newRecord) && oldRecord[i] != newRecord[i] {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// diffStatus values used in the "Diff" column of WriteCsv's output.
+const (
+	diffStatusAdded   = "added"
+	diffStatusRemoved = "removed"
+	diffStatusChanged = "changed"
+)
+
+// WriteCsv writes diff as a single CSV: the collection header plus a
+// leading "Diff" column of added/removed/changed, so that the output can
+// be consumed by anything that already reads a regular LicenceCollection
+// CSV. Changed rows are written twice, once for the old values and once
+// for the new, so that both revisions are visible.
+func (diff *LicenceDiff) WriteCsv(w *bufio.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(append([]string{"Diff"}, diff.header...)); err != nil {
+		return err
+	}
+
+	writeRow := func(status string, row *LicenceRow) error {
+		return writer.Write(append([]string{status}, diff.schema.Render(row)...))
+	}
+
+	for _, row := range diff.Added {
+		if err := writeRow(diffStatusAdded, row); err != nil {
+			return err
+		}
+	}
+	for _, row := range diff.Removed {
+		if err := writeRow(diffStatusRemoved, row); err != nil {
+			return err
+		}
+	}
+	for _, changed := range diff.Changed {
+		if err := writeRow(diffStatusChanged, changed.Old); err != nil {
+			return err
+		}
+		if err := writeRow(diffStatusChanged, changed.New); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,105 @@
+package wtr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"testing"
+)
+
+// newTestCollection builds a minimal LicenceCollection directly from
+// rows, bypassing LoadData, for use in table tests that don't need a
+// real WTR.csv on disk.
+func newTestCollection(rows ...*LicenceRow) *LicenceCollection {
+	return &LicenceCollection{header: schemaV1Header, rows: rows, schema: schemaV1{}}
+}
+
+func TestDiffFrequencyChangeIsChanged(t *testing.T) {
+	oldRow := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1800.000000"}
+	newRow := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1900.000000"}
+
+	diff := Diff(newTestCollection(oldRow), newTestCollection(newRow))
+
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("a frequency reassignment must be Changed, not Added/Removed: got %d added, %d removed", len(diff.Added), len(diff.Removed))
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("want 1 changed row, got %d", len(diff.Changed))
+	}
+	found := false
+	for _, field := range diff.Changed[0].Fields {
+		if field == "Frequency" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Changed.Fields missing \"Frequency\": %v", diff.Changed[0].Fields)
+	}
+}
+
+func TestDiffSameSiteMultipleFrequenciesAreKeptDistinct(t *testing.T) {
+	old1 := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1800.000000"}
+	old2 := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1900.000000"}
+	new1 := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1800.000000"}
+	new2 := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "2000.000000"}
+
+	diff := Diff(newTestCollection(old1, old2), newTestCollection(new1, new2))
+
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Fatalf("two same-site rows must not collapse onto each other: got %d added, %d removed", len(diff.Added), len(diff.Removed))
+	}
+	if len(diff.Changed) != 1 {
+		t.Fatalf("want exactly the second assignment reported Changed, got %d", len(diff.Changed))
+	}
+	if diff.Changed[0].Old != old2 || diff.Changed[0].New != new2 {
+		t.Fatalf("want the second same-site row's reassignment reported, got %+v", diff.Changed[0])
+	}
+}
+
+func TestDiffAddedAndRemoved(t *testing.T) {
+	common := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0"}
+	removed := &LicenceRow{LicenceNumber: "L2", SidLatDeg: "52", SidLongDeg: "1"}
+	added := &LicenceRow{LicenceNumber: "L3", SidLatDeg: "53", SidLongDeg: "2"}
+
+	diff := Diff(newTestCollection(common, removed), newTestCollection(common, added))
+
+	if len(diff.Added) != 1 || diff.Added[0] != added {
+		t.Fatalf("want added row L3, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != removed {
+		t.Fatalf("want removed row L2, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 0 {
+		t.Fatalf("unrelated rows must not be reported as Changed, got %v", diff.Changed)
+	}
+}
+
+func TestDiffWriteCsv(t *testing.T) {
+	oldRow := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1800.000000"}
+	newRow := &LicenceRow{LicenceNumber: "L1", SidLatDeg: "51", SidLongDeg: "0", Frequency: "1900.000000"}
+
+	diff := Diff(newTestCollection(oldRow), newTestCollection(newRow))
+
+	b := new(bytes.Buffer)
+	writer := bufio.NewWriter(b)
+	if err := diff.WriteCsv(writer); err != nil {
+		t.Fatal(err)
+	}
+	writer.Flush()
+
+	records, err := csv.NewReader(b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0][0] != "Diff" {
+		t.Fatalf("want leading Diff column, got %v", records[0])
+	}
+	// One changed row is written twice: once for the old values, once
+	// for the new.
+	if len(records) != 3 {
+		t.Fatalf("want header + 2 data rows, got %d rows", len(records))
+	}
+	if records[1][0] != diffStatusChanged || records[2][0] != diffStatusChanged {
+		t.Fatalf("want both data rows marked %q, got %q and %q", diffStatusChanged, records[1][0], records[2][0])
+	}
+}
@@ -0,0 +1,194 @@
+package wtr
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// FetchOptions configures Fetch.
+type FetchOptions struct {
+	// URL is the address of the WTR.csv (or .csv.gz / .zip) export.
+	URL string
+	// Client is used to make the request. http.DefaultClient is used if
+	// nil.
+	Client *http.Client
+	// UserAgent, if set, is sent as the request's User-Agent header.
+	UserAgent string
+	// SHA256, if set, is the expected hex-encoded SHA-256 checksum of
+	// the downloaded (pre-decompression) content. A mismatch is
+	// reported as an error.
+	SHA256 string
+}
+
+// fetchMeta is the ETag/Last-Modified sidecar persisted next to a
+// cached WTR.csv file, so that a later Fetch can make a conditional
+// request instead of re-downloading unchanged data.
+type fetchMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+func metaPath(cachePath string) string {
+	return cachePath + ".meta.json"
+}
+
+func loadFetchMeta(cachePath string) *fetchMeta {
+	data, err := os.ReadFile(metaPath(cachePath))
+	if err != nil {
+		return &fetchMeta{}
+	}
+	var meta fetchMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return &fetchMeta{}
+	}
+	return &meta
+}
+
+func saveFetchMeta(cachePath string, meta *fetchMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath(cachePath), data, 0644)
+}
+
+// Fetch downloads the WTR.csv export named by opts.URL, caching it at
+// cachePath. If a cached copy and its ETag/Last-Modified metadata exist,
+// Fetch sends a conditional request (If-None-Match / If-Modified-Since)
+// and, on a 304 Not Modified response, parses the cached copy instead of
+// downloading it again.
+//
+// A gzip- or zip-wrapped response is decompressed before being cached,
+// so cachePath always holds plain WTR.csv data.
+func Fetch(ctx context.Context, cachePath string, opts FetchOptions) (*LicenceCollection, error) {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	meta := loadFetchMeta(cachePath)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, opts.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if opts.UserAgent != "" {
+		req.Header.Set("User-Agent", opts.UserAgent)
+	}
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
+	}
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		f, err := os.Open(cachePath)
+		if err != nil {
+			return nil, fmt.Errorf("wtr: fetch: got 304 Not Modified but could not open cached copy: %w", err)
+		}
+		defer f.Close()
+		return LoadCollection(f)
+
+	case http.StatusOK:
+		return fetchFresh(resp, cachePath, opts)
+
+	default:
+		return nil, fmt.Errorf("wtr: fetch: unexpected HTTP status: %s", resp.Status)
+	}
+}
+
+// fetchFresh reads a 200 OK response body, verifies it, decompresses it
+// if necessary, writes it to cachePath alongside its caching metadata,
+// and parses the result.
+func fetchFresh(resp *http.Response, cachePath string, opts FetchOptions) (*LicenceCollection, error) {
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.ContentLength >= 0 && int64(len(data)) != resp.ContentLength {
+		return nil, fmt.Errorf("wtr: fetch: content length mismatch: got %d bytes, want %d", len(data), resp.ContentLength)
+	}
+
+	if opts.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, opts.SHA256) {
+			return nil, fmt.Errorf("wtr: fetch: sha256 mismatch: got %s, want %s", got, opts.SHA256)
+		}
+	}
+
+	csvData, err := decompress(data, resp.Header.Get("Content-Type"), opts.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(cachePath, csvData, 0644); err != nil {
+		return nil, err
+	}
+	if err := saveFetchMeta(cachePath, &fetchMeta{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}); err != nil {
+		return nil, err
+	}
+
+	return LoadCollection(bytes.NewReader(csvData))
+}
+
+// decompress returns the plain WTR.csv content of data, unwrapping a zip
+// or gzip container if contentType or sourceURL indicate one is present.
+func decompress(data []byte, contentType, sourceURL string) ([]byte, error) {
+	lowerURL := strings.ToLower(sourceURL)
+
+	switch {
+	// Checked before the zip case: a "gzip" Content-Type also contains
+	// the substring "zip", so matching zip first would misidentify every
+	// gzip response as a zip archive.
+	case strings.Contains(contentType, "gzip") || strings.HasSuffix(lowerURL, ".gz"):
+		gr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("wtr: fetch: reading gzip stream: %w", err)
+		}
+		defer gr.Close()
+		return io.ReadAll(gr)
+
+	case strings.Contains(contentType, "zip") || strings.HasSuffix(lowerURL, ".zip"):
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("wtr: fetch: reading zip archive: %w", err)
+		}
+		for _, f := range zr.File {
+			if !strings.HasSuffix(strings.ToLower(f.Name), ".csv") {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			return io.ReadAll(rc)
+		}
+		return nil, fmt.Errorf("wtr: fetch: zip archive contains no .csv file")
+
+	default:
+		return data, nil
+	}
+}
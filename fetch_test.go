@@ -0,0 +1,187 @@
+package wtr
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func sampleCsv() string {
+	row := make([]string, len(schemaV1Header))
+	row[0] = "L1"
+	return fmt.Sprintf("%s\n%s\n", joinCsv(schemaV1Header), joinCsv(row))
+}
+
+func joinCsv(fields []string) string {
+	var b bytes.Buffer
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(f)
+	}
+	return b.String()
+}
+
+func TestFetchDownloadsAndCaches(t *testing.T) {
+	csvText := sampleCsv()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		w.Write([]byte(csvText))
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	collection, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.rows) != 1 || collection.rows[0].LicenceNumber != "L1" {
+		t.Fatalf("want 1 row with LicenceNumber L1, got %+v", collection.rows)
+	}
+	if requests != 1 {
+		t.Fatalf("want 1 request, got %d", requests)
+	}
+
+	meta := loadFetchMeta(cachePath)
+	if meta.ETag != `"v1"` {
+		t.Fatalf("want cached ETag %q, got %q", `"v1"`, meta.ETag)
+	}
+}
+
+func TestFetchSendsConditionalRequestAndUsesCacheOn304(t *testing.T) {
+	csvText := sampleCsv()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(csvText))
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	if _, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second call should hit the 304 branch and parse the cached file.
+	collection, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.rows) != 1 || collection.rows[0].LicenceNumber != "L1" {
+		t.Fatalf("want 1 row with LicenceNumber L1 from cache, got %+v", collection.rows)
+	}
+}
+
+func TestFetchDecompressesZip(t *testing.T) {
+	csvText := sampleCsv()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zf, err := zw.Create("WTR.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := zf.Write([]byte(csvText)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/zip")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	collection, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL + "/WTR.zip"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.rows) != 1 || collection.rows[0].LicenceNumber != "L1" {
+		t.Fatalf("want 1 row with LicenceNumber L1, got %+v", collection.rows)
+	}
+}
+
+func TestFetchDecompressesGzip(t *testing.T) {
+	csvText := sampleCsv()
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write([]byte(csvText)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Content-Type (not Content-Encoding) signals the gzip wrapper
+		// here, so the transport's own transparent decompression (which
+		// keys off Content-Encoding) doesn't mask what Fetch itself does.
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(buf.Bytes())
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	collection, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL + "/WTR.csv.gz"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.rows) != 1 || collection.rows[0].LicenceNumber != "L1" {
+		t.Fatalf("want 1 row with LicenceNumber L1, got %+v", collection.rows)
+	}
+}
+
+func TestFetchSHA256Mismatch(t *testing.T) {
+	csvText := sampleCsv()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(csvText))
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	_, err := Fetch(context.Background(), cachePath, FetchOptions{
+		URL:    server.URL,
+		SHA256: hex.EncodeToString(sha256.New().Sum(nil)), // checksum of empty content, not csvText
+	})
+	if err == nil {
+		t.Fatal("want an error for a SHA-256 mismatch")
+	}
+}
+
+func TestFetchUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "WTR.csv")
+
+	_, err := Fetch(context.Background(), cachePath, FetchOptions{URL: server.URL})
+	if err == nil {
+		t.Fatal("want an error for an unexpected HTTP status")
+	}
+}
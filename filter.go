@@ -0,0 +1,95 @@
+package wtr
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// And returns a Predicate that keeps a row only if every one of
+// predicates keeps it. And() with no arguments keeps every row.
+func And(predicates ...Predicate) Predicate {
+	return func(row *LicenceRow) bool {
+		for _, p := range predicates {
+			if !p(row) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a Predicate that keeps a row if any one of predicates keeps
+// it. Or() with no arguments keeps no rows.
+func Or(predicates ...Predicate) Predicate {
+	return func(row *LicenceRow) bool {
+		for _, p := range predicates {
+			if p(row) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a Predicate that keeps a row iff p does not.
+func Not(p Predicate) Predicate {
+	return func(row *LicenceRow) bool {
+		return !p(row)
+	}
+}
+
+// FilterFrequencyRange keeps rows whose Frequency, in Hz, falls within
+// [minHz, maxHz]. The Frequency column is recorded in MHz; rows whose
+// Frequency cannot be parsed as a number are not kept.
+func FilterFrequencyRange(minHz, maxHz float64) Predicate {
+	return func(row *LicenceRow) bool {
+		mhz, err := strconv.ParseFloat(strings.TrimSpace(row.Frequency), 64)
+		if err != nil {
+			return false
+		}
+		hz := mhz * 1e6
+		return hz >= minHz && hz <= maxHz
+	}
+}
+
+// FilterNGRPrefix keeps rows whose NGR (National Grid Reference) begins
+// with one of prefixes.
+func FilterNGRPrefix(prefixes ...string) Predicate {
+	return func(row *LicenceRow) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(row.NGR, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// licenceDateLayout is the format Ofcom uses for the Licence issue date
+// column.
+const licenceDateLayout = "02/01/2006"
+
+// FilterIssuedBetween keeps rows whose Licence issue date falls within
+// [from, to]. Rows whose Licence issue date cannot be parsed are not
+// kept.
+func FilterIssuedBetween(from, to time.Time) Predicate {
+	return func(row *LicenceRow) bool {
+		issued, err := time.Parse(licenceDateLayout, strings.TrimSpace(row.LicenceIssueDate))
+		if err != nil {
+			return false
+		}
+		return !issued.Before(from) && !issued.After(to)
+	}
+}
+
+// FilterStatus keeps rows whose Status is one of statuses.
+func FilterStatus(statuses ...string) Predicate {
+	wanted := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		wanted[status] = true
+	}
+	return func(row *LicenceRow) bool {
+		return wanted[row.Status]
+	}
+}
@@ -0,0 +1,122 @@
+package wtr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAndKeepsRowOnlyIfAllPredicatesDo(t *testing.T) {
+	row := &LicenceRow{Status: "Active", ProductCode: "301010"}
+
+	allTrue := And(
+		func(row *LicenceRow) bool { return row.Status == "Active" },
+		func(row *LicenceRow) bool { return row.ProductCode == "301010" },
+	)
+	if !allTrue(row) {
+		t.Fatal("want And to keep a row every predicate keeps")
+	}
+
+	oneFalse := And(
+		func(row *LicenceRow) bool { return row.Status == "Active" },
+		func(row *LicenceRow) bool { return row.ProductCode == "999999" },
+	)
+	if oneFalse(row) {
+		t.Fatal("want And to drop a row any predicate drops")
+	}
+}
+
+func TestAndWithNoPredicatesKeepsEveryRow(t *testing.T) {
+	if !And()(&LicenceRow{}) {
+		t.Fatal("want And() to keep every row")
+	}
+}
+
+func TestOrKeepsRowIfAnyPredicateDoes(t *testing.T) {
+	row := &LicenceRow{Status: "Active"}
+
+	oneTrue := Or(
+		func(row *LicenceRow) bool { return row.Status == "Active" },
+		func(row *LicenceRow) bool { return false },
+	)
+	if !oneTrue(row) {
+		t.Fatal("want Or to keep a row any predicate keeps")
+	}
+
+	allFalse := Or(
+		func(row *LicenceRow) bool { return false },
+		func(row *LicenceRow) bool { return false },
+	)
+	if allFalse(row) {
+		t.Fatal("want Or to drop a row no predicate keeps")
+	}
+}
+
+func TestOrWithNoPredicatesKeepsNoRows(t *testing.T) {
+	if Or()(&LicenceRow{}) {
+		t.Fatal("want Or() to keep no rows")
+	}
+}
+
+func TestNotInvertsPredicate(t *testing.T) {
+	row := &LicenceRow{Status: "Active"}
+	isActive := func(row *LicenceRow) bool { return row.Status == "Active" }
+
+	if Not(isActive)(row) {
+		t.Fatal("want Not to invert a predicate that matches")
+	}
+	if !Not(isActive)(&LicenceRow{Status: "Revoked"}) {
+		t.Fatal("want Not to invert a predicate that doesn't match")
+	}
+}
+
+func TestFilterFrequencyRange(t *testing.T) {
+	keep := FilterFrequencyRange(1.8e9, 1.9e9)
+
+	if !keep(&LicenceRow{Frequency: "1850.000000"}) {
+		t.Fatal("want a frequency inside the range kept")
+	}
+	if keep(&LicenceRow{Frequency: "2000.000000"}) {
+		t.Fatal("want a frequency outside the range dropped")
+	}
+	if keep(&LicenceRow{Frequency: "not-a-number"}) {
+		t.Fatal("want an unparsable frequency dropped")
+	}
+}
+
+func TestFilterNGRPrefix(t *testing.T) {
+	keep := FilterNGRPrefix("TQ", "SU")
+
+	if !keep(&LicenceRow{NGR: "TQ123456"}) {
+		t.Fatal("want an NGR matching one of the prefixes kept")
+	}
+	if keep(&LicenceRow{NGR: "NY123456"}) {
+		t.Fatal("want an NGR matching no prefix dropped")
+	}
+}
+
+func TestFilterIssuedBetween(t *testing.T) {
+	from := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC)
+	keep := FilterIssuedBetween(from, to)
+
+	if !keep(&LicenceRow{LicenceIssueDate: "15/06/2020"}) {
+		t.Fatal("want a date inside the range kept")
+	}
+	if keep(&LicenceRow{LicenceIssueDate: "15/06/2021"}) {
+		t.Fatal("want a date outside the range dropped")
+	}
+	if keep(&LicenceRow{LicenceIssueDate: "not-a-date"}) {
+		t.Fatal("want an unparsable date dropped")
+	}
+}
+
+func TestFilterStatus(t *testing.T) {
+	keep := FilterStatus("Active", "Pending")
+
+	if !keep(&LicenceRow{Status: "Active"}) {
+		t.Fatal("want a matching status kept")
+	}
+	if keep(&LicenceRow{Status: "Revoked"}) {
+		t.Fatal("want a non-matching status dropped")
+	}
+}
@@ -0,0 +1,157 @@
+// This lives in the top-level wtr package rather than a separate
+// wtr/reporter package: without a go.mod, there is no module path a
+// nested package could use to import LicenceCollection/LicenceRow, so
+// it stays alongside them instead.
+package wtr
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// KeyFunc extracts the grouping key for a row, e.g. its Product Code or
+// Licencee Company.
+type KeyFunc func(row *LicenceRow) string
+
+// ByProductCode groups rows by their Product Code.
+func ByProductCode(row *LicenceRow) string { return row.ProductCode }
+
+// ByProductDescription groups rows by their Product Description.
+func ByProductDescription(row *LicenceRow) string { return row.ProductDescription }
+
+// ByLicenceeCompany groups rows by their Licencee Company.
+func ByLicenceeCompany(row *LicenceRow) string { return row.LicenceeCompany }
+
+// ByStationType groups rows by their Station Type.
+func ByStationType(row *LicenceRow) string { return row.StationType }
+
+// ReportOptions configures Generate.
+type ReportOptions struct {
+	// Key is the primary grouping key. Required.
+	Key KeyFunc
+	// KeyHeader is the column heading used for Key in the report.
+	KeyHeader string
+	// SecondKey, if set, adds a secondary grouping, producing a
+	// key/second-key/count cross-tab instead of a flat count.
+	SecondKey KeyFunc
+	// SecondKeyHeader is the column heading used for SecondKey. Defaults
+	// to "Second Key" when SecondKey is set and this is left blank.
+	SecondKeyHeader string
+	// CSV selects CSV output instead of the default tab-aligned text.
+	CSV bool
+}
+
+// count is one row of the report: a key (and optional second key) with
+// the number of LicenceRows that matched it.
+type count struct {
+	key, secondKey string
+	n              int
+}
+
+// Generate tabulates the rows of collection by opts.Key (and, if set,
+// opts.SecondKey), writing a sorted count report to w.
+//
+// Callers that want a report over a subset of the register should filter
+// first, e.g.:
+//
+//	Generate(coll.Filter(FilterCompanies("MBNL")), w, opts)
+func Generate(collection *LicenceCollection, w io.Writer, opts ReportOptions) error {
+	if opts.Key == nil {
+		return fmt.Errorf("wtr: reporter.Generate: ReportOptions.Key is required")
+	}
+	keyHeader := opts.KeyHeader
+	if keyHeader == "" {
+		keyHeader = "Key"
+	}
+	secondKeyHeader := opts.SecondKeyHeader
+	if opts.SecondKey != nil && secondKeyHeader == "" {
+		secondKeyHeader = "Second Key"
+	}
+
+	counts := make(map[string]*count)
+	var order []string
+	for _, row := range collection.rows {
+		key := opts.Key(row)
+		secondKey := ""
+		if opts.SecondKey != nil {
+			secondKey = opts.SecondKey(row)
+		}
+		id := key + "\x00" + secondKey
+		c, ok := counts[id]
+		if !ok {
+			c = &count{key: key, secondKey: secondKey}
+			counts[id] = c
+			order = append(order, id)
+		}
+		c.n++
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		a, b := counts[order[i]], counts[order[j]]
+		if a.key != b.key {
+			return a.key < b.key
+		}
+		return a.secondKey < b.secondKey
+	})
+
+	hasSecondKey := opts.SecondKey != nil
+
+	if opts.CSV {
+		return generateCsv(w, keyHeader, secondKeyHeader, hasSecondKey, order, counts)
+	}
+	return generateText(w, keyHeader, secondKeyHeader, hasSecondKey, order, counts)
+}
+
+func generateText(w io.Writer, keyHeader, secondKeyHeader string, hasSecondKey bool, order []string, counts map[string]*count) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if hasSecondKey {
+		fmt.Fprintf(tw, "%s\t%s\tCount\n", keyHeader, secondKeyHeader)
+	} else {
+		fmt.Fprintf(tw, "%s\tCount\n", keyHeader)
+	}
+
+	for _, id := range order {
+		c := counts[id]
+		if hasSecondKey {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", c.key, c.secondKey, c.n)
+		} else {
+			fmt.Fprintf(tw, "%s\t%d\n", c.key, c.n)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func generateCsv(w io.Writer, keyHeader, secondKeyHeader string, hasSecondKey bool, order []string, counts map[string]*count) error {
+	writer := csv.NewWriter(w)
+
+	var reportHeader []string
+	if hasSecondKey {
+		reportHeader = []string{keyHeader, secondKeyHeader, "Count"}
+	} else {
+		reportHeader = []string{keyHeader, "Count"}
+	}
+	if err := writer.Write(reportHeader); err != nil {
+		return err
+	}
+
+	for _, id := range order {
+		c := counts[id]
+		var record []string
+		if hasSecondKey {
+			record = []string{c.key, c.secondKey, fmt.Sprint(c.n)}
+		} else {
+			record = []string{c.key, fmt.Sprint(c.n)}
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,49 @@
+package wtr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenerateCrossTabWithoutSecondKeyHeader(t *testing.T) {
+	collection := newTestCollection(
+		&LicenceRow{ProductCode: "A", LicenceeCompany: "Acme"},
+		&LicenceRow{ProductCode: "A", LicenceeCompany: "Beta"},
+	)
+
+	var b bytes.Buffer
+	// Deliberately leave SecondKeyHeader unset: Generate must still
+	// treat this as a cross-tab, not collapse it into a flat count.
+	err := Generate(collection, &b, ReportOptions{Key: ByProductCode, SecondKey: ByLicenceeCompany})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want header + 2 rows (one per company), got %d lines:\n%s", len(lines), out)
+	}
+	if !strings.Contains(lines[0], "Second Key") {
+		t.Fatalf("want a default second-key column heading, got %q", lines[0])
+	}
+}
+
+func TestGenerateFlatCount(t *testing.T) {
+	collection := newTestCollection(
+		&LicenceRow{ProductCode: "A"},
+		&LicenceRow{ProductCode: "A"},
+		&LicenceRow{ProductCode: "B"},
+	)
+
+	var b bytes.Buffer
+	if err := Generate(collection, &b, ReportOptions{Key: ByProductCode, CSV: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Key,Count\nA,2\nB,1\n"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
@@ -0,0 +1,248 @@
+package wtr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Schema recognises one version of the Ofcom WTR.csv header and knows
+// how to parse rows written in that version's column order. Ofcom has
+// changed the WTR schema over time (added and renamed columns); new
+// versions are supported by registering an additional Schema rather than
+// by changing LoadData.
+type Schema interface {
+	// Detect reports whether header matches this schema version.
+	Detect(header []string) bool
+	// ParseRow parses a single CSV record into a LicenceRow.
+	ParseRow(record []string) (*LicenceRow, error)
+	// Render renders row back into this schema version's column order,
+	// the inverse of ParseRow.
+	Render(row *LicenceRow) []string
+	// Header returns the canonical header row for this schema version.
+	Header() []string
+}
+
+// schemas holds every registered Schema, most recently registered last.
+// detectSchema tries them in registration order, so earlier registrations
+// take priority when more than one could plausibly match.
+var schemas []Schema
+
+// registerSchema adds s to the set of schemas that LoadCollection will
+// try when detecting the version of an incoming WTR.csv header.
+func registerSchema(s Schema) {
+	schemas = append(schemas, s)
+}
+
+// detectSchema returns the first registered Schema whose Detect matches
+// header, or nil if none do.
+func detectSchema(header []string) Schema {
+	for _, s := range schemas {
+		if s.Detect(header) {
+			return s
+		}
+	}
+	return nil
+}
+
+func init() {
+	registerSchema(schemaV1{})
+	registerSchema(schemaV2{})
+}
+
+// schemaV1Header is the column order used by the original Ofcom
+// WTR.csv export.
+var schemaV1Header = []string{
+	"Licence Number",
+	"Licence issue date",
+	"SID_LAT_N_S",
+	"SID_LAT_DEG",
+	"SID_LAT_MIN",
+	"SID_LAT_SEC",
+	"SID_LONG_E_W",
+	"SID_LONG_DEG",
+	"SID_LONG_MIN",
+	"SID_LONG_SEC",
+	"NGR",
+	"Frequency",
+	"Frequency Type",
+	"Station Type",
+	"Channel Width",
+	"Channel Width type",
+	"Height above sea level",
+	"Antenna ERP",
+	"Antenna ERP type",
+	"Antenna Type",
+	"Antenna Gain",
+	"Antenna AZIMUTH",
+	"Horizontal Elements",
+	"Vertical Elements",
+	"Antenna Height",
+	"Antenna Location",
+	"EFL_UPPER_LOWER",
+	"Antenna Direction",
+	"Antenna Elevation",
+	"Antenna Polarisation",
+	"Antenna Name",
+	"Feeding Loss",
+	"Fade Margin",
+	"Emission Code",
+	"AP_COMMENT_INTERN",
+	"Vector",
+	"Licencee Surname",
+	"Licencee First Name",
+	"Licencee Company",
+	"Status",
+	"Tradeable",
+	"Publishable",
+	"Product Code",
+	"Product Description",
+	"Product Description 31",
+	"Product Description 32",
+}
+
+// schemaV1 is the original WTR.csv schema, in use since Ofcom first
+// published the register.
+type schemaV1 struct{}
+
+func (schemaV1) Header() []string { return schemaV1Header }
+
+func (schemaV1) Detect(header []string) bool {
+	return equalHeaders(header, schemaV1Header)
+}
+
+func (schemaV1) ParseRow(record []string) (*LicenceRow, error) {
+	if len(record) != len(schemaV1Header) {
+		return nil, fmt.Errorf("wtr: v1 schema: row has %d fields, want %d", len(record), len(schemaV1Header))
+	}
+	return parseLicenceRow(record), nil
+}
+
+func (schemaV1) Render(row *LicenceRow) []string { return row.toRecord() }
+
+// schemaV2Header is the column order Ofcom moved to when it started
+// publishing each licence's expiry alongside its issue date: "Licence
+// Expiry Date" was inserted immediately after "Licence issue date",
+// ahead of the same columns schemaV1 carries.
+var schemaV2Header = append(append(
+	append([]string{}, schemaV1Header[:2]...),
+	"Licence Expiry Date"),
+	schemaV1Header[2:]...,
+)
+
+// schemaV2 is the WTR.csv schema Ofcom moved to after adding a licence
+// expiry date column.
+type schemaV2 struct{}
+
+func (schemaV2) Header() []string { return schemaV2Header }
+
+func (schemaV2) Detect(header []string) bool {
+	return equalHeaders(header, schemaV2Header)
+}
+
+func (schemaV2) ParseRow(record []string) (*LicenceRow, error) {
+	if len(record) != len(schemaV2Header) {
+		return nil, fmt.Errorf("wtr: v2 schema: row has %d fields, want %d", len(record), len(schemaV2Header))
+	}
+	// record is schemaV1's layout with "Licence Expiry Date" spliced in
+	// at index 2; strip it back out so parseLicenceRow can be reused,
+	// then set it on the resulting row.
+	v1Record := make([]string, 0, len(schemaV1Header))
+	v1Record = append(v1Record, record[:2]...)
+	v1Record = append(v1Record, record[3:]...)
+
+	row := parseLicenceRow(v1Record)
+	row.LicenceExpiryDate = record[2]
+	return row, nil
+}
+
+func (schemaV2) Render(row *LicenceRow) []string {
+	v1Record := row.toRecord()
+	record := make([]string, 0, len(schemaV2Header))
+	record = append(record, v1Record[:2]...)
+	record = append(record, row.LicenceExpiryDate)
+	record = append(record, v1Record[2:]...)
+	return record
+}
+
+func equalHeaders(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SchemaError is returned by LoadCollection when no registered Schema
+// recognises a WTR.csv header, along with which of the known schema's
+// columns were missing or unrecognised to help diagnose a new Ofcom
+// export format.
+type SchemaError struct {
+	Header  []string
+	Unknown []string
+	Missing []string
+}
+
+// newSchemaError reports header against whichever registered schema it
+// overlaps with most, so the unknown/missing columns it lists point at
+// the closest known variant rather than always comparing to schemaV1.
+func newSchemaError(header []string) *SchemaError {
+	present := make(map[string]bool, len(header))
+	for _, name := range header {
+		present[name] = true
+	}
+
+	var closest Schema
+	bestOverlap := -1
+	for _, s := range schemas {
+		overlap := 0
+		for _, name := range s.Header() {
+			if present[name] {
+				overlap++
+			}
+		}
+		if overlap > bestOverlap {
+			bestOverlap = overlap
+			closest = s
+		}
+	}
+	if closest == nil {
+		return &SchemaError{Header: header}
+	}
+
+	known := make(map[string]bool, len(closest.Header()))
+	for _, name := range closest.Header() {
+		known[name] = true
+	}
+
+	var unknown []string
+	for _, name := range header {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	var missing []string
+	for _, name := range closest.Header() {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	return &SchemaError{Header: header, Unknown: unknown, Missing: missing}
+}
+
+func (e *SchemaError) Error() string {
+	var b strings.Builder
+	b.WriteString("wtr: unrecognised WTR.csv header")
+	if len(e.Unknown) > 0 {
+		fmt.Fprintf(&b, "; unknown column(s): %s", strings.Join(e.Unknown, ", "))
+	}
+	if len(e.Missing) > 0 {
+		fmt.Fprintf(&b, "; missing column(s): %s", strings.Join(e.Missing, ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package wtr
+
+import (
+	"strings"
+	"testing"
+)
+
+func v2Record() []string {
+	return make([]string, len(schemaV2Header))
+}
+
+func TestDetectSchemaV1(t *testing.T) {
+	schema := detectSchema(schemaV1Header)
+	if _, ok := schema.(schemaV1); !ok {
+		t.Fatalf("want schemaV1, got %#v", schema)
+	}
+}
+
+func TestDetectSchemaV2(t *testing.T) {
+	schema := detectSchema(schemaV2Header)
+	if _, ok := schema.(schemaV2); !ok {
+		t.Fatalf("want schemaV2, got %#v", schema)
+	}
+}
+
+func TestSchemaV2RoundTripsExpiryDate(t *testing.T) {
+	record := v2Record()
+	record[0] = "L1"
+	record[1] = "01/01/2020"
+	record[2] = "31/12/2029"
+
+	row, err := schemaV2{}.ParseRow(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.LicenceExpiryDate != "31/12/2029" {
+		t.Fatalf("want LicenceExpiryDate %q, got %q", "31/12/2029", row.LicenceExpiryDate)
+	}
+	if row.LicenceNumber != "L1" {
+		t.Fatalf("want LicenceNumber %q, got %q", "L1", row.LicenceNumber)
+	}
+
+	rendered := schemaV2{}.Render(row)
+	if len(rendered) != len(record) {
+		t.Fatalf("want %d fields, got %d", len(record), len(rendered))
+	}
+	if rendered[2] != "31/12/2029" {
+		t.Fatalf("want Render to place Licence Expiry Date at index 2, got %q", rendered[2])
+	}
+}
+
+func TestLoadCollectionUnknownHeaderReturnsSchemaError(t *testing.T) {
+	badHeader := append([]string{}, schemaV1Header...)
+	badHeader[0] = "Licence Reference" // renamed column
+
+	_, err := LoadCollection(strings.NewReader(strings.Join(badHeader, ",") + "\n"))
+	if err == nil {
+		t.Fatal("want an error for an unrecognised header")
+	}
+	schemaErr, ok := err.(*SchemaError)
+	if !ok {
+		t.Fatalf("want *SchemaError, got %T: %v", err, err)
+	}
+	if len(schemaErr.Unknown) == 0 {
+		t.Fatalf("want Unknown to list the renamed column, got %v", schemaErr)
+	}
+	if len(schemaErr.Missing) == 0 {
+		t.Fatalf("want Missing to list the original column name, got %v", schemaErr)
+	}
+}
+
+func TestLoadCollectionDetectsV2(t *testing.T) {
+	record := v2Record()
+	record[0] = "L1"
+	csvText := strings.Join(schemaV2Header, ",") + "\n" + strings.Join(record, ",") + "\n"
+
+	collection, err := LoadCollection(strings.NewReader(csvText))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.rows) != 1 {
+		t.Fatalf("want 1 row, got %d", len(collection.rows))
+	}
+	if collection.rows[0].LicenceNumber != "L1" {
+		t.Fatalf("want LicenceNumber %q, got %q", "L1", collection.rows[0].LicenceNumber)
+	}
+}
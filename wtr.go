@@ -0,0 +1,348 @@
+// Package wtr loads, filters and writes Ofcom's Wireless Telegraphy
+// Register (WTR.csv) licence data.
+//
+// The register lists every licensed radio transmitter in the UK: fixed
+// links, broadcast transmitters, mobile network infrastructure and so
+// on. This package provides a thin, CSV-shaped model over that data so
+// that callers can load a snapshot, slice it by product code, company or
+// frequency, and write the result back out in the same format.
+package wtr
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// LicenceRow is a single row of the WTR register: one station/frequency
+// assignment against a licence.
+type LicenceRow struct {
+	LicenceNumber    string
+	LicenceIssueDate string
+	// LicenceExpiryDate is only populated by schemas that carry it (see
+	// schemaV2 in schema.go); it is empty for rows parsed by schemaV1.
+	LicenceExpiryDate    string
+	SidLatNS             string
+	SidLatDeg            string
+	SidLatMin            string
+	SidLatSec            string
+	SidLongEW            string
+	SidLongDeg           string
+	SidLongMin           string
+	SidLongSec           string
+	NGR                  string
+	Frequency            string
+	FrequencyType        string
+	StationType          string
+	ChannelWidth         string
+	ChannelWidthType     string
+	HeightAboveSeaLevel  string
+	AntennaERP           string
+	AntennaERPType       string
+	AntennaType          string
+	AntennaGain          string
+	AntennaAzimuth       string
+	HorizontalElements   string
+	VerticalElements     string
+	AntennaHeight        string
+	AntennaLocation      string
+	EFLUpperLower        string
+	AntennaDirection     string
+	AntennaElevation     string
+	AntennaPolarisation  string
+	AntennaName          string
+	FeedingLoss          string
+	FadeMargin           string
+	EmissionCode         string
+	APCommentIntern      string
+	Vector               string
+	LicenceeSurname      string
+	LicenceeFirstName    string
+	LicenceeCompany      string
+	Status               string
+	Tradeable            string
+	Publishable          string
+	ProductCode          string
+	ProductDescription   string
+	ProductDescription31 string
+	ProductDescription32 string
+}
+
+// parseLicenceRow builds a LicenceRow from a single CSV record laid out
+// in the v1 schema's column order (see schemaV1 in schema.go), which
+// must already have been validated to have the right number of fields.
+func parseLicenceRow(record []string) *LicenceRow {
+	return &LicenceRow{
+		LicenceNumber:        record[0],
+		LicenceIssueDate:     record[1],
+		SidLatNS:             record[2],
+		SidLatDeg:            record[3],
+		SidLatMin:            record[4],
+		SidLatSec:            record[5],
+		SidLongEW:            record[6],
+		SidLongDeg:           record[7],
+		SidLongMin:           record[8],
+		SidLongSec:           record[9],
+		NGR:                  record[10],
+		Frequency:            record[11],
+		FrequencyType:        record[12],
+		StationType:          record[13],
+		ChannelWidth:         record[14],
+		ChannelWidthType:     record[15],
+		HeightAboveSeaLevel:  record[16],
+		AntennaERP:           record[17],
+		AntennaERPType:       record[18],
+		AntennaType:          record[19],
+		AntennaGain:          record[20],
+		AntennaAzimuth:       record[21],
+		HorizontalElements:   record[22],
+		VerticalElements:     record[23],
+		AntennaHeight:        record[24],
+		AntennaLocation:      record[25],
+		EFLUpperLower:        record[26],
+		AntennaDirection:     record[27],
+		AntennaElevation:     record[28],
+		AntennaPolarisation:  record[29],
+		AntennaName:          record[30],
+		FeedingLoss:          record[31],
+		FadeMargin:           record[32],
+		EmissionCode:         record[33],
+		APCommentIntern:      record[34],
+		Vector:               record[35],
+		LicenceeSurname:      record[36],
+		LicenceeFirstName:    record[37],
+		LicenceeCompany:      record[38],
+		Status:               record[39],
+		Tradeable:            record[40],
+		Publishable:          record[41],
+		ProductCode:          record[42],
+		ProductDescription:   record[43],
+		ProductDescription31: record[44],
+		ProductDescription32: record[45],
+	}
+}
+
+// toRecord renders the row back into the v1 schema's CSV column order.
+func (row *LicenceRow) toRecord() []string {
+	return []string{
+		row.LicenceNumber,
+		row.LicenceIssueDate,
+		row.SidLatNS,
+		row.SidLatDeg,
+		row.SidLatMin,
+		row.SidLatSec,
+		row.SidLongEW,
+		row.SidLongDeg,
+		row.SidLongMin,
+		row.SidLongSec,
+		row.NGR,
+		row.Frequency,
+		row.FrequencyType,
+		row.StationType,
+		row.ChannelWidth,
+		row.ChannelWidthType,
+		row.HeightAboveSeaLevel,
+		row.AntennaERP,
+		row.AntennaERPType,
+		row.AntennaType,
+		row.AntennaGain,
+		row.AntennaAzimuth,
+		row.HorizontalElements,
+		row.VerticalElements,
+		row.AntennaHeight,
+		row.AntennaLocation,
+		row.EFLUpperLower,
+		row.AntennaDirection,
+		row.AntennaElevation,
+		row.AntennaPolarisation,
+		row.AntennaName,
+		row.FeedingLoss,
+		row.FadeMargin,
+		row.EmissionCode,
+		row.APCommentIntern,
+		row.Vector,
+		row.LicenceeSurname,
+		row.LicenceeFirstName,
+		row.LicenceeCompany,
+		row.Status,
+		row.Tradeable,
+		row.Publishable,
+		row.ProductCode,
+		row.ProductDescription,
+		row.ProductDescription31,
+		row.ProductDescription32,
+	}
+}
+
+// LicenceCollection holds a set of WTR licence rows together with the
+// header they were loaded from and the Schema that parsed them.
+type LicenceCollection struct {
+	header []string
+	rows   []*LicenceRow
+	schema Schema
+}
+
+// LoadData reads a WTR.csv file from path and returns the resulting
+// LicenceCollection. It fatals on any read, parse or schema-detection
+// error, since a licence collection that cannot be fully loaded is not
+// useful to any of the callers of this package.
+func LoadData(path string) *LicenceCollection {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	collection, err := LoadCollection(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return collection
+}
+
+// LoadCollection reads WTR.csv data from r, detecting which registered
+// Schema the header row matches and using it to parse every subsequent
+// row. It returns a *SchemaError if no registered Schema recognises the
+// header.
+func LoadCollection(r io.Reader) (*LicenceCollection, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("wtr: empty WTR.csv")
+	}
+	head := records[0]
+
+	schema := detectSchema(head)
+	if schema == nil {
+		return nil, newSchemaError(head)
+	}
+
+	rows := make([]*LicenceRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row, err := schema.ParseRow(record)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+
+	return &LicenceCollection{header: head, rows: rows, schema: schema}, nil
+}
+
+// WriteCsv writes the collection back out in WTR.csv format, including
+// the header row.
+func (collection *LicenceCollection) WriteCsv(w *bufio.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(collection.header); err != nil {
+		return err
+	}
+	for _, row := range collection.rows {
+		if err := writer.Write(collection.schema.Render(row)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Predicate reports whether row should be kept by a filter.
+type Predicate func(row *LicenceRow) bool
+
+// Filter returns a new LicenceCollection containing only the rows of
+// collection for which keep returns true. The header is copied
+// unchanged.
+func (collection *LicenceCollection) Filter(keep Predicate) *LicenceCollection {
+	rows := make([]*LicenceRow, 0, len(collection.rows))
+	for _, row := range collection.rows {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	return &LicenceCollection{header: collection.header, rows: rows, schema: collection.schema}
+}
+
+// FilterInPlace removes, in place, any row of collection for which keep
+// returns false.
+func (collection *LicenceCollection) FilterInPlace(keep Predicate) {
+	rows := collection.rows[:0]
+	for _, row := range collection.rows {
+		if keep(row) {
+			rows = append(rows, row)
+		}
+	}
+	collection.rows = rows
+}
+
+// pointToPointProductCodes are the Product Code values that identify a
+// fixed point-to-point link, as opposed to broadcast, mobile or other
+// station types.
+var pointToPointProductCodes = map[string]bool{
+	"301010": true,
+	"301020": true,
+	"301030": true,
+}
+
+// FilterPointToPoint keeps rows whose Product Code identifies a fixed
+// point-to-point link.
+func FilterPointToPoint(row *LicenceRow) bool {
+	return pointToPointProductCodes[row.ProductCode]
+}
+
+// FilterProductCodes keeps rows whose Product Code is one of codes.
+func FilterProductCodes(codes ...string) Predicate {
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		wanted[code] = true
+	}
+	return func(row *LicenceRow) bool {
+		return wanted[row.ProductCode]
+	}
+}
+
+// FilterCompanies keeps rows whose Licencee Company is one of companies.
+func FilterCompanies(companies ...string) Predicate {
+	wanted := make(map[string]bool, len(companies))
+	for _, company := range companies {
+		wanted[company] = true
+	}
+	return func(row *LicenceRow) bool {
+		return wanted[row.LicenceeCompany]
+	}
+}
+
+// GetProductCodes returns the set of Product Codes known to this
+// package, keyed by code.
+func GetProductCodes() map[string]bool {
+	codes := map[string]bool{
+		"301010": true,
+		"301020": true,
+		"301030": true,
+		"302010": true,
+		"303010": true,
+		"304010": true,
+		"305010": true,
+		"306010": true,
+	}
+	return codes
+}
+
+// GetCompanies returns the distinct Licencee Company values present in
+// the collection, in first-seen order.
+func (collection *LicenceCollection) GetCompanies() []string {
+	seen := make(map[string]bool)
+	var companies []string
+	for _, row := range collection.rows {
+		if row.LicenceeCompany == "" || seen[row.LicenceeCompany] {
+			continue
+		}
+		seen[row.LicenceeCompany] = true
+		companies = append(companies, row.LicenceeCompany)
+	}
+	return companies
+}